@@ -1,35 +1,120 @@
 package circuit
 
 import (
-	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"fmt"
+
+	"gnark-poc/circuit/rangeproof"
+	"gnark-poc/circuit/tree"
+
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	gnarkhash "github.com/consensys/gnark/std/hash"
 	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/hash/poseidon2"
 	"github.com/consensys/gnark/std/signature/eddsa"
 )
 
 const MaxOutputs = 10
 
+// HashAlgo selects the hash used for output commitments and the EdDSA
+// message in Define. Before HashAlgo existed the circuit always hashed with
+// MiMC; HashPoseidon2 switches those hashes to Poseidon2 when a caller asks
+// for it. HashMiMC (the zero value) keeps existing verifying keys working
+// unchanged.
+type HashAlgo int
+
+const (
+	HashMiMC HashAlgo = iota
+	HashPoseidon2
+)
+
+// newHasher returns a fresh in-circuit hasher for algo. Callers get a new
+// instance per hash rather than calling Reset(), matching how the
+// Merkle/nullifier hashing elsewhere in Define already does it.
+func newHasher(api frontend.API, algo HashAlgo) (gnarkhash.FieldHasher, error) {
+	switch algo {
+	case HashPoseidon2:
+		return poseidon2.NewMerkleDamgardHasher(api)
+	case HashMiMC:
+		h, err := mimc.NewMiMC(api)
+		if err != nil {
+			return nil, err
+		}
+		return &h, nil
+	default:
+		return nil, fmt.Errorf("circuit: unknown HashAlgo %d", algo)
+	}
+}
+
+// TreeDepth is the UTXO Merkle tree depth. Fixed at compile time (it sizes
+// MerklePath/MerklePathIndices below) and must match circuit/tree.Depth,
+// since that's what produces the paths this circuit verifies.
+const TreeDepth = tree.Depth
+
+// DefaultRangeBits is the bit width outputs are constrained to when a
+// TokenTransferCircuit's RangeBits field is left at its zero value, so
+// existing call sites that build the struct literally (`var c
+// TokenTransferCircuit`) keep the 64-bit behaviour without having to set
+// anything.
+const DefaultRangeBits = rangeproof.Width64
+
 // TokenTransferCircuit defines the circuit for privacy-preserving token transfers.
 // It verifies:
 // 1. Balance conservation
 // 2. Token type preservation
 // 3. Ownership authorization (EdDSA signature)
+// 4. Output amounts are in-range (no field-overflow "negative" outputs)
 type TokenTransferCircuit struct {
 	// Public inputs
 	OutputAmounts      [MaxOutputs]frontend.Variable `gnark:",public"`
 	OutputTokenParams  [MaxOutputs]frontend.Variable `gnark:",public"`
 	OutputOwnerPubKeyX [MaxOutputs]frontend.Variable `gnark:",public"`
 
+	// Public inputs: UTXO nullifier subsystem
+	MerkleRoot frontend.Variable `gnark:",public"`
+	Nullifier  frontend.Variable `gnark:",public"`
+
 	// Private inputs
 	InputAmount    frontend.Variable
 	TokenParams    frontend.Variable
 	Signature      eddsa.Signature
 	OriginalPubKey eddsa.PublicKey
+
+	// Private inputs: UTXO nullifier subsystem. InputCommitment is the leaf
+	// being spent; MerklePath/MerklePathIndices are its inclusion proof
+	// under MerkleRoot (see circuit/tree.Tree.Prove); NullifierSecret is a
+	// value only the owner can produce (e.g. derived from their private
+	// key) that's hashed with InputCommitment to bind Nullifier to this
+	// specific spend without revealing which leaf it was.
+	InputCommitment   frontend.Variable
+	MerklePath        [TreeDepth]frontend.Variable
+	MerklePathIndices [TreeDepth]frontend.Variable
+	NullifierSecret   frontend.Variable
+
+	// RangeBits is the bit width each OutputAmount is range-checked against
+	// (see circuit/rangeproof). It's plain circuit configuration, not a
+	// witness value, so it must be set identically before both Compile and
+	// NewWitness - changing it changes the R1CS shape. Zero means
+	// DefaultRangeBits.
+	RangeBits rangeproof.Width
+
+	// HashAlgo picks the hash used for output commitments and the EdDSA
+	// message (see HashAlgo above). Like RangeBits, it's circuit
+	// configuration, not a witness value. Zero means HashMiMC.
+	HashAlgo HashAlgo
 }
 
 // Define declares the circuit constraints
 func (c *TokenTransferCircuit) Define(api frontend.API) error {
+	// 0. Range Checks: every output amount must fit in RangeBits, so a
+	// witness can't satisfy balance conservation (constraint 1 below) with
+	// a field-overflow value that's actually negative mod p.
+	rangeBits := c.RangeBits
+	if rangeBits == 0 {
+		rangeBits = DefaultRangeBits
+	}
+	rangeproof.AssertAllInRange(api, c.OutputAmounts[:], rangeBits)
+
 	// 1. Balance Conservation: inputAmount == sum(outputAmounts)
 	totalOut := frontend.Variable(0)
 	for i := 0; i < MaxOutputs; i++ {
@@ -46,56 +131,101 @@ func (c *TokenTransferCircuit) Define(api frontend.API) error {
 		api.AssertIsEqual(check, 0)
 	}
 
-	// 3. Compute output commitments using Poseidon
-	// We use gnark's std/hash/poseidon2 for compatibility
-	// outputData0 = poseidon2([outputAmounts[0], outputTokenParams[0], outputOwnerPubKeyX[0]]);
-	// outputData1 = poseidon2([outputAmounts[1], outputTokenParams[1], outputOwnerPubKeyX[1]]);
-	// outputCommitment = poseidon2([outputData0, outputData1]);
-
-	// Poseidon instance (swapped to MiMC for compatibility)
-	hasher, _ := mimc.NewMiMC(api)
-
-	// Output 0
-	hasher.Write(c.OutputAmounts[0])
-	hasher.Write(c.OutputTokenParams[0])
-	hasher.Write(c.OutputOwnerPubKeyX[0])
-	outputData0 := hasher.Sum()
-	hasher.Reset()
-
-	// Output 1
-	hasher.Write(c.OutputAmounts[1])
-	hasher.Write(c.OutputTokenParams[1])
-	hasher.Write(c.OutputOwnerPubKeyX[1])
-	outputData1 := hasher.Sum()
-	hasher.Reset()
-
-	// Final Commitment
-	hasher.Write(outputData0)
-	hasher.Write(outputData1)
-	outputCommitment := hasher.Sum()
-    
-    // We don't have outputCommitment as a public input in this circuit struct to assert against,
-    // but we use it for the signature verification message below.
+	// 3. Compute output commitments using HashAlgo (HashMiMC unless the
+	// caller asked for HashPoseidon2 - see HashAlgo above).
+	// outputData0 = hash([outputAmounts[0], outputTokenParams[0], outputOwnerPubKeyX[0]]);
+	// outputData1 = hash([outputAmounts[1], outputTokenParams[1], outputOwnerPubKeyX[1]]);
+	// outputCommitment = hash([outputData0, outputData1]);
+	hasher0, err := newHasher(api, c.HashAlgo)
+	if err != nil {
+		return err
+	}
+	hasher0.Write(c.OutputAmounts[0])
+	hasher0.Write(c.OutputTokenParams[0])
+	hasher0.Write(c.OutputOwnerPubKeyX[0])
+	outputData0 := hasher0.Sum()
+
+	hasher1, err := newHasher(api, c.HashAlgo)
+	if err != nil {
+		return err
+	}
+	hasher1.Write(c.OutputAmounts[1])
+	hasher1.Write(c.OutputTokenParams[1])
+	hasher1.Write(c.OutputOwnerPubKeyX[1])
+	outputData1 := hasher1.Sum()
+
+	commitmentHasher, err := newHasher(api, c.HashAlgo)
+	if err != nil {
+		return err
+	}
+	commitmentHasher.Write(outputData0)
+	commitmentHasher.Write(outputData1)
+	outputCommitment := commitmentHasher.Sum()
+
+	// We don't have outputCommitment as a public input in this circuit struct to assert against,
+	// but we use it for the signature verification message below.
 
 	// 4. EdDSA Signature Verification
 	// We need the curve parameters. Zokrates uses BabyJubJub.
 	// Gnark's twistededwards package supports BN254 (which BabyJubJub is embedded in).
-	curve, err := twistededwards.NewEdCurve(api, tedwards.BN254)
+	curve, err := twistededwards.NewEdCurve(api, InnerEdCurve)
+	if err != nil {
+		return err
+	}
+
+	// Message Construction
+	// We sign (InputAmount, TokenParams, OutputCommitment) to match general security model
+
+	msgHasher, err := newHasher(api, c.HashAlgo)
 	if err != nil {
 		return err
 	}
+	msgHasher.Write(c.InputAmount)
+	msgHasher.Write(c.TokenParams)
+	msgHasher.Write(outputCommitment)
+	message := msgHasher.Sum()
+
+	// Create a fresh hasher for EdDSA signature verification
+	// (the hasher passed to Verify is used to compute H(R, A, M)). Same
+	// HashAlgo as the commitment/message hashing above, since the native
+	// side (wasm's sign()) has to produce the signature with a matching
+	// hash or it won't verify here.
+	eddsaHasher, err := newHasher(api, c.HashAlgo)
+	if err != nil {
+		return err
+	}
+	if err := eddsa.Verify(curve, c.Signature, message, c.OriginalPubKey, eddsaHasher); err != nil {
+		return err
+	}
+
+	// 5. UTXO Merkle Inclusion: InputCommitment must be a leaf under
+	// MerkleRoot. Same per-level hash-and-select shape as gnark's
+	// std/accumulator/merkle gadget, inlined with a fresh MiMC instance per
+	// level. This is always MiMC, whatever HashAlgo says, to match
+	// circuit/tree's hashPair.
+	cur := c.InputCommitment
+	for i := 0; i < TreeDepth; i++ {
+		api.AssertIsBoolean(c.MerklePathIndices[i])
+
+		sibling := c.MerklePath[i]
+		left := api.Select(c.MerklePathIndices[i], sibling, cur)
+		right := api.Select(c.MerklePathIndices[i], cur, sibling)
+
+		levelHasher, _ := mimc.NewMiMC(api)
+		levelHasher.Write(left)
+		levelHasher.Write(right)
+		cur = levelHasher.Sum()
+	}
+	api.AssertIsEqual(cur, c.MerkleRoot)
+
+	// 6. Nullifier: binds this spend to InputCommitment via a secret only
+	// the owner knows, so double-spends can be rejected out-of-circuit by
+	// refusing to see the same Nullifier twice (circuit/tree.Nullifiers)
+	// without the tree ever learning which leaf was spent.
+	nullifierHasher, _ := mimc.NewMiMC(api)
+	nullifierHasher.Write(c.NullifierSecret)
+	nullifierHasher.Write(c.InputCommitment)
+	api.AssertIsEqual(nullifierHasher.Sum(), c.Nullifier)
 
-    // Message Construction
-    // We sign (InputAmount, TokenParams, OutputCommitment) to match general security model
-    
-    msgHasher, _ := mimc.NewMiMC(api)
-    msgHasher.Write(c.InputAmount)
-    msgHasher.Write(c.TokenParams)
-    msgHasher.Write(outputCommitment)
-    message := msgHasher.Sum()
-
-    // Create a fresh hasher for EdDSA signature verification
-    // (the hasher passed to Verify is used to compute H(R, A, M))
-    eddsaHasher, _ := mimc.NewMiMC(api)
-    return eddsa.Verify(curve, c.Signature, message, c.OriginalPubKey, &eddsaHasher)
+	return nil
 }
@@ -0,0 +1,16 @@
+//go:build bls12377
+
+package circuit
+
+import (
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// InnerCurveID/InnerEdCurve for the bls12377 build tag. See curve_bn254.go
+// for why this build exists: it's the inner-proof curve for
+// circuit/recursive's BatchedTransferCircuit, which verifies these proofs
+// from a BW6-761 outer circuit.
+const InnerCurveID = ecc.BLS12_377
+
+var InnerEdCurve = tedwards.BLS12_377
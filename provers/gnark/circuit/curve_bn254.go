@@ -0,0 +1,21 @@
+//go:build !bls12377
+
+package circuit
+
+import (
+	tedwards "github.com/consensys/gnark-crypto/ecc/twistededwards"
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// InnerCurveID is the scalar field the circuit is compiled for, and
+// InnerEdCurve is the embedded twisted-edwards curve used for EdDSA
+// ownership checks. This is the default build: everything lives on BN254,
+// same as the original single-proof flow.
+//
+// Build with -tags bls12377 to switch both to the BLS12-377 pairing, which
+// is what circuit/recursive needs for its inner proofs (BW6-761 can verify
+// BLS12-377 Groth16 proofs efficiently in-circuit; it can't do the same for
+// BN254).
+const InnerCurveID = ecc.BN254
+
+var InnerEdCurve = tedwards.BN254
@@ -0,0 +1,104 @@
+package circuit
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/poseidon2"
+	"github.com/consensys/gnark/frontend"
+)
+
+// hasherAgreementCircuit feeds Ins through newHasher(Algo) and asserts the
+// result equals Out, so TestHasherAgreesWithNative can pin the in-circuit
+// hash (what TokenTransferCircuit.Define actually proves over) against the
+// native, out-of-circuit hash (what cmd/testvectors/main.go, and any other
+// off-circuit caller building a witness, computes) for the same inputs.
+type hasherAgreementCircuit struct {
+	Ins  [3]frontend.Variable
+	Out  frontend.Variable `gnark:",public"`
+	Algo HashAlgo
+}
+
+func (c *hasherAgreementCircuit) Define(api frontend.API) error {
+	h, err := newHasher(api, c.Algo)
+	if err != nil {
+		return err
+	}
+	h.Write(c.Ins[0], c.Ins[1], c.Ins[2])
+	api.AssertIsEqual(h.Sum(), c.Out)
+	return nil
+}
+
+// nativeHash mirrors hasherAgreementCircuit.Define's
+// newHasher(algo).Write(ins...).Sum() sequence out-of-circuit, the same
+// way cmd/testvectors/main.go computes its native side.
+func nativeHash(algo HashAlgo, ins [3]string) (*big.Int, error) {
+	type hasher interface {
+		Write(p []byte) (int, error)
+		Sum(b []byte) []byte
+	}
+	var h hasher
+	switch algo {
+	case HashPoseidon2:
+		h = poseidon2.NewPoseidon2()
+	default:
+		h = mimc.NewMiMC()
+	}
+
+	for _, in := range ins {
+		var e bn254fr.Element
+		if _, err := e.SetString(in); err != nil {
+			return nil, err
+		}
+		b := e.Bytes()
+		h.Write(b[:])
+	}
+
+	var out bn254fr.Element
+	out.SetBytes(h.Sum(nil))
+	return out.BigInt(new(big.Int)), nil
+}
+
+// sampleTriples mirrors cmd/testvectors/main.go's sampleInputSets, zero
+// extended to width 3 so a single circuit shape covers every vector.
+var sampleTriples = [][3]string{
+	{"0", "0", "0"},
+	{"1", "0", "0"},
+	{"1", "2", "3"},
+	{"12345678901234567890", "0", "0"},
+}
+
+// TestHasherAgreesWithNative regenerates cmd/testvectors' sample inputs
+// through newHasher in-circuit (via frontend.IsSolved) and through
+// gnark-crypto natively, for both HashAlgo values, and fails if they ever
+// disagree - the check testvectors/poseidon2_bn254.json's expectedHash
+// values exist to make possible, but that a JSON file on its own can't
+// perform.
+func TestHasherAgreesWithNative(t *testing.T) {
+	for _, algo := range []HashAlgo{HashMiMC, HashPoseidon2} {
+		for _, ins := range sampleTriples {
+			expected, err := nativeHash(algo, ins)
+			if err != nil {
+				t.Fatalf("algo=%v ins=%v: native hash: %v", algo, ins, err)
+			}
+
+			assignment := hasherAgreementCircuit{Algo: algo, Out: expected}
+			for i, v := range ins {
+				assignment.Ins[i] = v
+			}
+
+			w, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+			if err != nil {
+				t.Fatalf("algo=%v ins=%v: witness: %v", algo, ins, err)
+			}
+
+			placeholder := hasherAgreementCircuit{Algo: algo}
+			if err := frontend.IsSolved(&placeholder, w, ecc.BN254.ScalarField()); err != nil {
+				t.Fatalf("algo=%v ins=%v: in-circuit hash disagreed with native: %v", algo, ins, err)
+			}
+		}
+	}
+}
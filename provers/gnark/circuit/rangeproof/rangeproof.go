@@ -0,0 +1,47 @@
+// Package rangeproof constrains circuit values to a fixed bit width so a
+// Groth16 witness can't satisfy balance conservation with a field-overflow
+// "negative" amount (an output that's actually p-k for some k, which still
+// sums correctly modulo the scalar field).
+//
+// The check is the same arithmetization Bulletproofs' inner-product range
+// proof is built on - decompose the value into booleans and assert they
+// recombine to it - just done directly as R1CS constraints instead of via
+// an external inner-product argument.
+package rangeproof
+
+import (
+	"github.com/consensys/gnark/frontend"
+)
+
+// Width is a supported range-proof bit width. Kept as its own type (rather
+// than a bare int) so setup artifacts can record which one was used without
+// ambiguity.
+type Width int
+
+const (
+	Width32  Width = 32
+	Width64  Width = 64
+	Width128 Width = 128
+)
+
+// AssertInRange constrains v to lie in [0, 2^width). api.ToBinary(v, width)
+// already both boolean-constrains each decomposed bit and asserts they
+// recompose to v, so that's the whole check - an earlier version also
+// re-asserted booleanity per bit and re-asserted the recomposition itself,
+// which only tripled the constraints per value for no additional soundness.
+func AssertInRange(api frontend.API, v frontend.Variable, width Width) {
+	api.ToBinary(v, int(width))
+}
+
+// AssertAllInRange range-checks every value in vs by calling AssertInRange
+// on each. There's nothing to batch: ToBinary already fully enforces the
+// range per value, so an earlier version that folded the per-value checks
+// into one randomized linear combination (meant to share a single equality
+// assertion across all of vs) was pure overhead - a MiMC permutation over
+// every value plus a power-chain of multiplications, checking nothing
+// AssertInRange hadn't already.
+func AssertAllInRange(api frontend.API, vs []frontend.Variable, width Width) {
+	for _, v := range vs {
+		AssertInRange(api, v, width)
+	}
+}
@@ -0,0 +1,82 @@
+// Package recursive implements in-circuit verification of N inner
+// TokenTransferCircuit Groth16 proofs, so a wallet can collapse a batch of
+// transfers into a single aggregated proof that's cheap to verify on-chain.
+//
+// The inner proofs are produced over BLS12-377 (see circuit.InnerCurveID
+// under the bls12377 build tag)
+// because BN254 can't efficiently verify its own Groth16 proofs in-circuit.
+// BW6-761 is the standard pairing-friendly "outer" curve for that pairing,
+// so the batch circuit itself must be compiled/setup over BW6-761.
+//
+// Batch aggregation is reachable today via cmd/main.go's compile-batch/
+// setup-batch/prove-batch actions (built with -tags bls12377). It does not
+// have a WASM entrypoint: cmd/wasm/main.go is a GOOS=js build with no
+// bls12377 tag, so it's wired to BN254 and can't also offer a working
+// BW6-761 gnarkProveBatch in the same binary (see the comment on that
+// file's global variables). A -tags bls12377 WASM build exposing gnarkProveBatch was the
+// originally requested deliverable and still isn't provided - batching only
+// exists as a CLI path for now.
+package recursive
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+)
+
+// BatchSize is how many inner TokenTransferCircuit proofs one
+// BatchedTransferCircuit instance aggregates. Kept as a compile-time
+// constant (like circuit.MaxOutputs) so the R1CS shape is fixed.
+const BatchSize = 8
+
+// InnerField/InnerG1/InnerG2/InnerGT name the BLS12-377 curve types plugged
+// into the stdgroth16 generic recursion gadget (Proof[G1,G2],
+// VerifyingKey[G1,G2,GT], Witness[FR], NewVerifier[FR,G1,G2,GT]) to verify
+// inner BLS12-377 Groth16 proofs inside this BW6-761 circuit. BLS12-377's
+// base field is BW6-761's scalar field, so sw_bls12377 represents group
+// elements natively rather than through nonnative/emulated arithmetic.
+type (
+	InnerField = sw_bls12377.ScalarField
+	InnerG1    = sw_bls12377.G1Affine
+	InnerG2    = sw_bls12377.G2Affine
+	InnerGT    = sw_bls12377.GT
+)
+
+// BatchedTransferCircuit verifies BatchSize independent TokenTransferCircuit
+// Groth16 proofs against a single, shared inner verifying key, and exposes a
+// single outer Groth16 proof that attests "all BatchSize inner proofs are
+// valid, over these specific inner public witnesses". The inner verifying
+// key is constant across proofs (every inner proof was produced by the same
+// TokenTransferCircuit setup), so it's fixed as a circuit input rather than
+// baked in per-proof.
+//
+// InnerWitnesses is tagged public so each inner proof's MerkleRoot/Nullifier
+// (and its other public fields) are committed outer public inputs, not just
+// an opaque "some valid batch" attestation - an on-chain verifier needs
+// those to check nullifiers against the spent set and tie the batch to real
+// state. InnerProofs stays private: the outer proof's own validity already
+// attests to what the inner proofs prove, so there's no reason to spend
+// calldata re-exposing the inner Groth16 points themselves.
+type BatchedTransferCircuit struct {
+	InnerVK stdgroth16.VerifyingKey[InnerG1, InnerG2, InnerGT]
+
+	InnerProofs    [BatchSize]stdgroth16.Proof[InnerG1, InnerG2]
+	InnerWitnesses [BatchSize]stdgroth16.Witness[InnerField] `gnark:",public"`
+}
+
+// Define verifies each inner proof in turn. A single failing inner proof
+// fails the whole batch - that's the point, it's what lets the chain accept
+// one proof instead of BatchSize of them.
+func (c *BatchedTransferCircuit) Define(api frontend.API) error {
+	verifier, err := stdgroth16.NewVerifier[InnerField, InnerG1, InnerG2, InnerGT](api)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < BatchSize; i++ {
+		if err := verifier.AssertProof(c.InnerVK, c.InnerProofs[i], c.InnerWitnesses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package tree
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Nullifiers tracks spent nullifiers so a UTXO can't be proven twice. A
+// valid proof only shows the input commitment exists somewhere in the tree
+// and that Nullifier was derived from it correctly - it's this set, checked
+// outside the circuit, that actually stops the second spend.
+type Nullifiers struct {
+	mu    sync.Mutex
+	spent map[fr.Element]struct{}
+}
+
+// NewNullifiers returns an empty spent-nullifier set.
+func NewNullifiers() *Nullifiers {
+	return &Nullifiers{spent: make(map[fr.Element]struct{})}
+}
+
+// MarkSpent records nullifier as spent. It returns an error instead of
+// silently double-marking, so a caller that forgets to check IsSpent first
+// still can't slip a replay through.
+func (n *Nullifiers) MarkSpent(nullifier fr.Element) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, spent := n.spent[nullifier]; spent {
+		return fmt.Errorf("tree: nullifier already spent")
+	}
+	n.spent[nullifier] = struct{}{}
+	return nil
+}
+
+// IsSpent reports whether nullifier has already been marked spent.
+func (n *Nullifiers) IsSpent(nullifier fr.Element) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, spent := n.spent[nullifier]
+	return spent
+}
@@ -0,0 +1,43 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+func TestNullifiersRejectDoubleSpend(t *testing.T) {
+	n := NewNullifiers()
+	var nf fr.Element
+	nf.SetUint64(42)
+
+	if n.IsSpent(nf) {
+		t.Fatal("fresh nullifier should not already be spent")
+	}
+	if err := n.MarkSpent(nf); err != nil {
+		t.Fatalf("MarkSpent on a fresh nullifier: %v", err)
+	}
+	if !n.IsSpent(nf) {
+		t.Fatal("nullifier should be spent after MarkSpent")
+	}
+	if err := n.MarkSpent(nf); err == nil {
+		t.Fatal("MarkSpent on an already-spent nullifier should error, not silently succeed")
+	}
+}
+
+func TestNullifiersTrackIndependently(t *testing.T) {
+	n := NewNullifiers()
+	var a, b fr.Element
+	a.SetUint64(1)
+	b.SetUint64(2)
+
+	if err := n.MarkSpent(a); err != nil {
+		t.Fatalf("MarkSpent(a): %v", err)
+	}
+	if n.IsSpent(b) {
+		t.Fatal("marking a as spent should not mark b as spent")
+	}
+	if err := n.MarkSpent(b); err != nil {
+		t.Fatalf("MarkSpent(b): %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+// Package tree maintains the out-of-circuit side of the UTXO set: an
+// append-only Merkle tree of output commitments, hashed with the same MiMC
+// construction the circuit uses so paths produced here verify inside
+// TokenTransferCircuit.Define unchanged.
+package tree
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+)
+
+// Depth is the tree's fixed depth, matching circuit.TreeDepth. 32 levels
+// supports up to 2^32 commitments, which is far more than this POC will
+// ever insert, but keeping it a compile-time constant is what lets the
+// circuit's MerklePath/MerklePathIndices arrays have a fixed size.
+const Depth = 32
+
+// Tree is an append-only Merkle tree of leaf commitments. It's not
+// concurrency-safe - callers serialize inserts themselves (e.g. behind the
+// same mutex that assigns UTXO indices).
+type Tree struct {
+	leaves []fr.Element
+	// zero[i] is the hash of an empty subtree of height i - precomputed so
+	// Root/Prove don't need special-casing for not-yet-inserted leaves.
+	zero [Depth + 1]fr.Element
+}
+
+// New returns an empty depth-Depth tree.
+func New() *Tree {
+	t := &Tree{}
+	t.zero[0] = fr.Element{} // empty leaf hashes to zero
+	for i := 1; i <= Depth; i++ {
+		t.zero[i] = hashPair(t.zero[i-1], t.zero[i-1])
+	}
+	return t
+}
+
+// Insert appends a new leaf commitment and returns its index.
+func (t *Tree) Insert(leaf fr.Element) int {
+	t.leaves = append(t.leaves, leaf)
+	return len(t.leaves) - 1
+}
+
+// Root returns the current tree root.
+func (t *Tree) Root() fr.Element {
+	if len(t.leaves) == 0 {
+		// layerRoot/nextLayer fold zero[i] in only when hashing a pair, so an
+		// empty leaf layer (nextLayer short-circuits it to nil at every
+		// level) would otherwise bottom out at zero[0] instead of zero[Depth].
+		return t.zero[Depth]
+	}
+	return t.layerRoot(t.currentLayer(), Depth)
+}
+
+// Prove returns the sibling path and left/right indices for leafIndex, in
+// the same bottom-up order TokenTransferCircuit.Define walks them: path[0]
+// is the leaf's sibling, path[Depth-1] is the sibling just below the root.
+// indices[i] == 1 means the leaf-side node at level i is the right child
+// (so the circuit must place the sibling on the left).
+func (t *Tree) Prove(leafIndex int) (path [Depth]fr.Element, indices [Depth]int, err error) {
+	if leafIndex < 0 || leafIndex >= len(t.leaves) {
+		return path, indices, fmt.Errorf("tree: leaf index %d out of range (have %d leaves)", leafIndex, len(t.leaves))
+	}
+
+	layer := t.currentLayer()
+	idx := leafIndex
+	for level := 0; level < Depth; level++ {
+		siblingIdx := idx ^ 1
+		if siblingIdx < len(layer) {
+			path[level] = layer[siblingIdx]
+		} else {
+			path[level] = t.zero[level]
+		}
+		indices[level] = idx & 1
+
+		layer = nextLayer(layer, t.zero[level])
+		idx >>= 1
+	}
+	return path, indices, nil
+}
+
+// currentLayer is the leaf layer, padded conceptually with zero[0] hashes
+// by the rest of the tree's logic (nextLayer/layerRoot read past the end
+// via t.zero).
+func (t *Tree) currentLayer() []fr.Element {
+	return t.leaves
+}
+
+func (t *Tree) layerRoot(layer []fr.Element, levelsRemaining int) fr.Element {
+	if levelsRemaining == 0 {
+		if len(layer) == 0 {
+			return t.zero[0]
+		}
+		return layer[0]
+	}
+	zero := t.zero[Depth-levelsRemaining]
+	return t.layerRoot(nextLayer(layer, zero), levelsRemaining-1)
+}
+
+// nextLayer hashes `layer` pairwise, using `zero` in place of a missing
+// right-hand sibling at the end of an odd-length layer.
+func nextLayer(layer []fr.Element, zero fr.Element) []fr.Element {
+	if len(layer) == 0 {
+		return nil
+	}
+	out := make([]fr.Element, (len(layer)+1)/2)
+	for i := range out {
+		left := layer[2*i]
+		right := zero
+		if 2*i+1 < len(layer) {
+			right = layer[2*i+1]
+		}
+		out[i] = hashPair(left, right)
+	}
+	return out
+}
+
+func hashPair(left, right fr.Element) fr.Element {
+	h := mimc.NewMiMC()
+	lb := left.Bytes()
+	rb := right.Bytes()
+	h.Write(lb[:])
+	h.Write(rb[:])
+	var out fr.Element
+	out.SetBytes(h.Sum(nil))
+	return out
+}
@@ -0,0 +1,95 @@
+package tree
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+func leafFromUint64(v uint64) fr.Element {
+	var e fr.Element
+	e.SetUint64(v)
+	return e
+}
+
+// verifyPath walks path/indices the same way TokenTransferCircuit.Define
+// does: at each level, the sibling goes on whichever side indices[level]
+// says the leaf-side node isn't on.
+func verifyPath(leaf fr.Element, path [Depth]fr.Element, indices [Depth]int, root fr.Element) bool {
+	cur := leaf
+	for level := 0; level < Depth; level++ {
+		sibling := path[level]
+		var left, right fr.Element
+		if indices[level] == 1 {
+			left, right = sibling, cur
+		} else {
+			left, right = cur, sibling
+		}
+		cur = hashPair(left, right)
+	}
+	return cur.Equal(&root)
+}
+
+func TestEmptyTreeRootMatchesZeroDepth(t *testing.T) {
+	tr := New()
+	if !tr.Root().Equal(&tr.zero[Depth]) {
+		t.Fatal("empty tree root should equal zero[Depth]")
+	}
+}
+
+func TestInsertChangesRoot(t *testing.T) {
+	tr := New()
+	before := tr.Root()
+	tr.Insert(leafFromUint64(1))
+	after := tr.Root()
+	if before.Equal(&after) {
+		t.Fatal("inserting a leaf should change the root")
+	}
+}
+
+func TestProveRoundTrips(t *testing.T) {
+	tr := New()
+	leaves := []fr.Element{leafFromUint64(1), leafFromUint64(2), leafFromUint64(3)}
+	for _, l := range leaves {
+		tr.Insert(l)
+	}
+	root := tr.Root()
+
+	for i, l := range leaves {
+		path, indices, err := tr.Prove(i)
+		if err != nil {
+			t.Fatalf("Prove(%d): %v", i, err)
+		}
+		if !verifyPath(l, path, indices, root) {
+			t.Fatalf("Prove(%d): inclusion path did not verify against Root()", i)
+		}
+	}
+}
+
+func TestProveOutOfRange(t *testing.T) {
+	tr := New()
+	tr.Insert(leafFromUint64(1))
+
+	if _, _, err := tr.Prove(-1); err == nil {
+		t.Fatal("expected error for negative leaf index")
+	}
+	if _, _, err := tr.Prove(1); err == nil {
+		t.Fatal("expected error for leaf index beyond the number of leaves")
+	}
+}
+
+func TestProveAfterLaterInserts(t *testing.T) {
+	tr := New()
+	tr.Insert(leafFromUint64(1))
+	tr.Insert(leafFromUint64(2))
+	tr.Insert(leafFromUint64(3))
+	root := tr.Root()
+
+	path, indices, err := tr.Prove(0)
+	if err != nil {
+		t.Fatalf("Prove(0) after further inserts: %v", err)
+	}
+	if !verifyPath(leafFromUint64(1), path, indices, root) {
+		t.Fatal("Prove(0) after later inserts did not verify against the current root")
+	}
+}
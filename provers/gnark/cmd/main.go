@@ -1,22 +1,42 @@
 package main
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"gnark-poc/circuit"
+	"gnark-poc/circuit/rangeproof"
+	"gnark-poc/circuit/recursive"
+	"gnark-poc/verifier/solidity"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
 )
 
+// innerProofDTO is one entry of the JSON array fed to prove-batch: an inner
+// TokenTransferCircuit proof plus the public witness it was produced
+// against. The proof is the hex encoding of the same groth16.Proof bytes the
+// WASM entrypoint hands back to the caller; the public witness is a list of
+// decimal strings.
+type innerProofDTO struct {
+	Proof         string   `json:"proof"`         // hex-encoded groth16.Proof bytes
+	PublicWitness []string `json:"publicWitness"` // decimal field elements, in circuit declaration order
+}
+
 func main() {
-	action := flag.String("action", "compile", "Action to perform: compile, setup, prove, verify")
+	action := flag.String("action", "compile", "Action to perform: compile, setup, prove, verify, compile-batch, setup-batch, prove-batch, verifier-solidity")
 	outputDir := flag.String("output", "artifacts", "Output directory for artifacts")
+	innerVkPath := flag.String("inner-vk", "artifacts/verifier.vk", "Path to the inner TokenTransferCircuit verifying key (prove-batch)")
+	proofsPath := flag.String("proofs", "proofs.json", "Path to a JSON array of {proof, publicWitness} inner proofs (prove-batch)")
+	rangeBits := flag.Int("range-bits", int(circuit.DefaultRangeBits), "Output amount range-proof bit width: 32, 64, or 128")
 	flag.Parse()
 
 	// Ensure output directory exists
@@ -24,12 +44,20 @@ func main() {
 		os.MkdirAll(*outputDir, 0755)
 	}
 
+	switch rangeproof.Width(*rangeBits) {
+	case rangeproof.Width32, rangeproof.Width64, rangeproof.Width128:
+	default:
+		fmt.Printf("Unsupported -range-bits %d (want 32, 64 or 128)\n", *rangeBits)
+		os.Exit(1)
+	}
+
 	var myCircuit circuit.TokenTransferCircuit
+	myCircuit.RangeBits = rangeproof.Width(*rangeBits)
 
 	switch *action {
 	case "compile":
 		fmt.Println("Compiling circuit...")
-		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &myCircuit)
+		ccs, err := frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &myCircuit)
 		if err != nil {
 			panic(err)
 		}
@@ -53,7 +81,7 @@ func main() {
 		
 		// For simplicity in this script: Compile everywhere or read?
 		// Let's compile inside setup to avoid serialization issues if versions change.
-		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &myCircuit)
+		ccs, err := frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &myCircuit)
 		if err != nil {
 			panic(err)
 		}
@@ -71,16 +99,220 @@ func main() {
 		fVk, _ := os.Create(filepath.Join(*outputDir, "verifier.vk"))
 		vk.WriteTo(fVk)
 		fVk.Close()
-		
+
+		writeSetupMetadata(*outputDir, myCircuit.RangeBits)
+
 		fmt.Println("Setup complete. Keys generated.")
 
 	case "prove":
 		// This is for CLI usage. For WASM we will likely have a separate entry or build tag.
 		// We'll leave this empty for now or basic implementation.
 		fmt.Println("Prove mode (CLI) not fully implemented yet - focusing on WASM.")
-		
+
+	case "verifier-solidity":
+		fmt.Println("Exporting Solidity verifier...")
+		vk := groth16.NewVerifyingKey(circuit.InnerCurveID)
+		fVk, err := os.Open(filepath.Join(*outputDir, "verifier.vk"))
+		if err != nil {
+			panic(err)
+		}
+		if _, err := vk.ReadFrom(fVk); err != nil {
+			panic(err)
+		}
+		fVk.Close()
+
+		fSol, err := os.Create(filepath.Join(*outputDir, "Verifier.sol"))
+		if err != nil {
+			panic(err)
+		}
+		defer fSol.Close()
+		if err := solidity.Export(vk, fSol); err != nil {
+			panic(err)
+		}
+		fmt.Printf("Verifier.sol written to %s\n", filepath.Join(*outputDir, "Verifier.sol"))
+
+	case "compile-batch":
+		// NOTE: build this action with `-tags bls12377` so circuit.InnerCurveID
+		// is BLS12-377 - that's the inner proof curve BW6-761 (the outer curve
+		// here, always) can verify efficiently in-circuit.
+		fmt.Println("Compiling inner circuit to size recursion placeholders...")
+		innerCcs, err := frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &circuit.TokenTransferCircuit{})
+		if err != nil {
+			panic(err)
+		}
+
+		outer := recursive.BatchedTransferCircuit{
+			InnerVK: newInnerVKPlaceholder(innerCcs),
+		}
+		for i := 0; i < recursive.BatchSize; i++ {
+			outer.InnerProofs[i] = newInnerProofPlaceholder(innerCcs)
+			outer.InnerWitnesses[i] = newInnerWitnessPlaceholder(innerCcs)
+		}
+
+		fmt.Println("Compiling batch (outer, BW6-761) circuit...")
+		batchCcs, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &outer)
+		if err != nil {
+			panic(err)
+		}
+
+		f, _ := os.Create(filepath.Join(*outputDir, "batch.ccs"))
+		batchCcs.WriteTo(f)
+		f.Close()
+		fmt.Printf("Batch circuit compiled. Constraints: %d (batch size %d)\n", batchCcs.GetNbConstraints(), recursive.BatchSize)
+
+	case "setup-batch":
+		fmt.Println("Running trusted setup for the batch circuit...")
+		innerCcs, err := frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &circuit.TokenTransferCircuit{})
+		if err != nil {
+			panic(err)
+		}
+
+		outer := recursive.BatchedTransferCircuit{
+			InnerVK: newInnerVKPlaceholder(innerCcs),
+		}
+		for i := 0; i < recursive.BatchSize; i++ {
+			outer.InnerProofs[i] = newInnerProofPlaceholder(innerCcs)
+			outer.InnerWitnesses[i] = newInnerWitnessPlaceholder(innerCcs)
+		}
+
+		batchCcs, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &outer)
+		if err != nil {
+			panic(err)
+		}
+
+		pk, vk, err := groth16.Setup(batchCcs)
+		if err != nil {
+			panic(err)
+		}
+
+		fPk, _ := os.Create(filepath.Join(*outputDir, "prover.batch.pk"))
+		pk.WriteTo(fPk)
+		fPk.Close()
+
+		fVk, _ := os.Create(filepath.Join(*outputDir, "verifier.batch.vk"))
+		vk.WriteTo(fVk)
+		fVk.Close()
+
+		fmt.Println("Batch setup complete. Keys generated.")
+
+	case "prove-batch":
+		fmt.Printf("Loading %d inner proofs from %s...\n", recursive.BatchSize, *proofsPath)
+		raw, err := os.ReadFile(*proofsPath)
+		if err != nil {
+			panic(err)
+		}
+		var dtos []innerProofDTO
+		if err := json.Unmarshal(raw, &dtos); err != nil {
+			panic(err)
+		}
+		if len(dtos) != recursive.BatchSize {
+			panic(fmt.Sprintf("expected %d inner proofs, got %d", recursive.BatchSize, len(dtos)))
+		}
+
+		innerVk := groth16.NewVerifyingKey(circuit.InnerCurveID)
+		fVk, err := os.Open(*innerVkPath)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := innerVk.ReadFrom(fVk); err != nil {
+			panic(err)
+		}
+		fVk.Close()
+
+		var outerAssignment recursive.BatchedTransferCircuit
+		outerAssignment.InnerVK, err = stdgroth16.ValueOfVerifyingKey[recursive.InnerG1, recursive.InnerG2, recursive.InnerGT](innerVk)
+		if err != nil {
+			panic(err)
+		}
+
+		for i, dto := range dtos {
+			proofBytes, err := hex.DecodeString(dto.Proof)
+			if err != nil {
+				panic(fmt.Sprintf("proof %d: %v", i, err))
+			}
+			innerProof := groth16.NewProof(circuit.InnerCurveID)
+			if _, err := innerProof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+				panic(fmt.Sprintf("proof %d: %v", i, err))
+			}
+
+			outerAssignment.InnerProofs[i], err = stdgroth16.ValueOfProof[recursive.InnerG1, recursive.InnerG2](innerProof)
+			if err != nil {
+				panic(fmt.Sprintf("proof %d: %v", i, err))
+			}
+
+			innerPublicWitness, err := publicWitnessFromDecimalStrings(dto.PublicWitness)
+			if err != nil {
+				panic(fmt.Sprintf("proof %d: %v", i, err))
+			}
+			outerAssignment.InnerWitnesses[i], err = stdgroth16.ValueOfWitness[recursive.InnerField](innerPublicWitness)
+			if err != nil {
+				panic(fmt.Sprintf("proof %d: %v", i, err))
+			}
+		}
+
+		// Recompile the batch CCS rather than deserializing batch.ccs, same
+		// "compile everywhere" shortcut the single-proof setup/compile
+		// actions above take.
+		innerCcs, err := frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &circuit.TokenTransferCircuit{})
+		if err != nil {
+			panic(err)
+		}
+		placeholder := recursive.BatchedTransferCircuit{InnerVK: newInnerVKPlaceholder(innerCcs)}
+		for i := 0; i < recursive.BatchSize; i++ {
+			placeholder.InnerProofs[i] = newInnerProofPlaceholder(innerCcs)
+			placeholder.InnerWitnesses[i] = newInnerWitnessPlaceholder(innerCcs)
+		}
+		batchCcs, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, &placeholder)
+		if err != nil {
+			panic(err)
+		}
+
+		batchPk := groth16.NewProvingKey(ecc.BW6_761)
+		fPk, err := os.Open(filepath.Join(*outputDir, "prover.batch.pk"))
+		if err != nil {
+			panic(err)
+		}
+		if _, err := batchPk.ReadFrom(fPk); err != nil {
+			panic(err)
+		}
+		fPk.Close()
+
+		w, err := frontend.NewWitness(&outerAssignment, ecc.BW6_761.ScalarField())
+		if err != nil {
+			panic(err)
+		}
+
+		batchProof, err := groth16.Prove(batchCcs, batchPk, w)
+		if err != nil {
+			panic(err)
+		}
+
+		fProof, _ := os.Create(filepath.Join(*outputDir, "aggregated.proof"))
+		batchProof.WriteTo(fProof)
+		fProof.Close()
+		fmt.Printf("Aggregated proof written, covering %d inner transfers.\n", recursive.BatchSize)
+
 	default:
 		fmt.Println("Unknown action")
 		os.Exit(1)
 	}
 }
+
+// setupMetadata records the circuit parameters a given artifacts directory
+// was generated with, so a verifier/prover loading prover.pk/verifier.vk
+// later doesn't have to guess what RangeBits the setup ran with.
+type setupMetadata struct {
+	RangeBits int `json:"rangeBits"`
+}
+
+func writeSetupMetadata(outputDir string, rangeBits rangeproof.Width) {
+	f, err := os.Create(filepath.Join(outputDir, "metadata.json"))
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(setupMetadata{RangeBits: int(rangeBits)}); err != nil {
+		panic(err)
+	}
+}
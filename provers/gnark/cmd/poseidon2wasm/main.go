@@ -0,0 +1,48 @@
+//go:build js && wasm
+
+// Command poseidon2wasm exposes gnark-crypto's native Poseidon2 hasher to
+// JS as gnarkPoseidon2Hash, so scripts/verify_testvectors.js can diff
+// testvectors/poseidon2_bn254.json against the real BN254 Poseidon2
+// implementation instead of re-deriving its constants in JS (circomlibjs's
+// Poseidon is a different permutation and can never agree with it).
+//
+// Build with:
+//
+//	GOOS=js GOARCH=wasm go build -o testvectors.wasm ./cmd/poseidon2wasm
+package main
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/poseidon2"
+)
+
+func main() {
+	c := make(chan struct{}, 0)
+	js.Global().Set("gnarkPoseidon2Hash", js.FuncOf(hash))
+	<-c
+}
+
+// hash takes args[0] = a JS array of decimal-string field elements and
+// returns poseidon2.NewPoseidon2()'s hash of them as a decimal string -
+// the exact computation cmd/testvectors/main.go used to produce
+// testvectors/poseidon2_bn254.json's expectedHash values.
+func hash(this js.Value, args []js.Value) interface{} {
+	inputs := args[0]
+	h := poseidon2.NewPoseidon2()
+	for i := 0; i < inputs.Length(); i++ {
+		s := inputs.Index(i).String()
+		var e fr.Element
+		if _, err := e.SetString(s); err != nil {
+			return map[string]interface{}{"error": fmt.Sprintf("bad input %q: %v", s, err)}
+		}
+		b := e.Bytes()
+		h.Write(b[:])
+	}
+
+	var out fr.Element
+	out.SetBytes(h.Sum(nil))
+	return out.String()
+}
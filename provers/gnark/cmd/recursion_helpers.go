@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math/big"
+
+	"gnark-poc/circuit"
+	"gnark-poc/circuit/recursive"
+
+	"github.com/consensys/gnark/constraint"
+	stdgroth16 "github.com/consensys/gnark/std/recursion/groth16"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// These three wrap the stdgroth16 Placeholder* helpers so compile-batch,
+// setup-batch and prove-batch all size their recursive.BatchedTransferCircuit
+// assignment off the same inner CCS instead of repeating the type params
+// everywhere.
+
+func newInnerVKPlaceholder(innerCcs constraint.ConstraintSystem) stdgroth16.VerifyingKey[recursive.InnerG1, recursive.InnerG2, recursive.InnerGT] {
+	return stdgroth16.PlaceholderVerifyingKey[recursive.InnerG1, recursive.InnerG2, recursive.InnerGT](innerCcs)
+}
+
+func newInnerProofPlaceholder(innerCcs constraint.ConstraintSystem) stdgroth16.Proof[recursive.InnerG1, recursive.InnerG2] {
+	return stdgroth16.PlaceholderProof[recursive.InnerG1, recursive.InnerG2](innerCcs)
+}
+
+func newInnerWitnessPlaceholder(innerCcs constraint.ConstraintSystem) stdgroth16.Witness[recursive.InnerField] {
+	return stdgroth16.PlaceholderWitness[recursive.InnerField](innerCcs)
+}
+
+// publicWitnessFromDecimalStrings turns the decimal field-element strings in
+// an innerProofDTO.PublicWitness into a public-only witness.Witness, the
+// shape stdgroth16.ValueOfWitness expects.
+func publicWitnessFromDecimalStrings(values []string) (witness.Witness, error) {
+	w, err := witness.New(circuit.InnerCurveID.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+
+	chValues := make(chan any)
+	go func() {
+		defer close(chValues)
+		for _, v := range values {
+			n := new(big.Int)
+			n.SetString(v, 10)
+			chValues <- n
+		}
+	}()
+
+	if err := w.Fill(len(values), 0, chValues); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
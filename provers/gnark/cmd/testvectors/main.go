@@ -0,0 +1,90 @@
+// Command testvectors (re)generates testvectors/*.json: fixed sample
+// inputs hashed with both circuit.HashMiMC and circuit.HashPoseidon2,
+// native (out-of-circuit) side. circuit.TestHasherAgreesWithNative checks
+// the in-circuit hashers against the native ones on these sample inputs
+// zero-padded to three elements; it computes its own expected values and
+// does not read the JSON files written here. scripts/verify_testvectors.js
+// cross-checks poseidon2_bn254.json against the real gnark-crypto
+// Poseidon2 compiled to WASM (cmd/poseidon2wasm).
+//
+// Run from provers/gnark:
+//
+//	go run ./cmd/testvectors
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/poseidon2"
+)
+
+// sampleInputSets are small, fixed, human-picked input vectors - not meant
+// to cover every edge case, just enough that a regression in either hasher
+// (or a mismatch with the WASM-compiled Poseidon2) shows up as a diff.
+var sampleInputSets = [][]string{
+	{"0"},
+	{"1"},
+	{"1", "2", "3"},
+	{"12345678901234567890"},
+}
+
+type vector struct {
+	Inputs       []string `json:"inputs"`
+	ExpectedHash string   `json:"expectedHash"`
+}
+
+func main() {
+	outDir := "testvectors"
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		panic(err)
+	}
+
+	writeVectors(filepath.Join(outDir, "mimc_bn254.json"), func() hasher { return mimc.NewMiMC() })
+	writeVectors(filepath.Join(outDir, "poseidon2_bn254.json"), func() hasher { return poseidon2.NewPoseidon2() })
+
+	fmt.Println("Test vectors written to", outDir)
+}
+
+// hasher is the subset of hash.Hash both mimc.NewMiMC() and
+// poseidon2.NewPoseidon2() implement - it's all this generator needs.
+type hasher interface {
+	Write(p []byte) (int, error)
+	Sum(b []byte) []byte
+	Reset()
+}
+
+func writeVectors(path string, newHasher func() hasher) {
+	vectors := make([]vector, 0, len(sampleInputSets))
+	for _, inputs := range sampleInputSets {
+		h := newHasher()
+		for _, in := range inputs {
+			var e fr.Element
+			if _, err := e.SetString(in); err != nil {
+				panic(fmt.Sprintf("bad sample input %q: %v", in, err))
+			}
+			b := e.Bytes()
+			h.Write(b[:])
+		}
+
+		var out fr.Element
+		out.SetBytes(h.Sum(nil))
+		vectors = append(vectors, vector{Inputs: inputs, ExpectedHash: out.String()})
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(vectors); err != nil {
+		panic(err)
+	}
+}
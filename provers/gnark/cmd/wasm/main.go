@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"syscall/js"
 
 	"gnark-poc/circuit"
@@ -13,6 +14,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
     "github.com/consensys/gnark-crypto/ecc/bn254/fr"
     "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+    "github.com/consensys/gnark-crypto/ecc/bn254/fr/poseidon2"
     "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/constraint"
@@ -21,6 +23,13 @@ import (
 )
 
 // Global variables to hold reusable components
+//
+// There is no WASM batch (recursive aggregation) path: this binary is built
+// GOOS=js with no bls12377 tag, so circuit.InnerCurveID is BN254 here, and
+// the outer BW6-761 batch circuit can only verify BLS12-377 inner proofs.
+// One wasm module can't offer both a BN254 gnarkProve and a working
+// BW6-761 gnarkProveBatch - see cmd/main.go's compile-batch/setup-batch/
+// prove-batch actions (built with -tags bls12377) for batch aggregation.
 var (
 	pk  groth16.ProvingKey
 	vk  groth16.VerifyingKey
@@ -36,34 +45,63 @@ func main() {
 	js.Global().Set("gnarkProve", js.FuncOf(prove))
 	js.Global().Set("gnarkVerify", js.FuncOf(verify))
 	js.Global().Set("gnarkGetConstraints", js.FuncOf(getConstraints))
+	js.Global().Set("gnarkInitBegin", js.FuncOf(gnarkInitBegin))
+	js.Global().Set("gnarkInitChunk", js.FuncOf(gnarkInitChunk))
+	js.Global().Set("gnarkInitCommit", js.FuncOf(gnarkInitCommit))
+	js.Global().Set("gnarkCancelInit", js.FuncOf(gnarkCancelInit))
 
 	println("Gnark WASM initialized")
 	<-c
 }
 
+// nativeHasher is the out-of-circuit (gnark-crypto) counterpart of
+// circuit.newHasher: same HashAlgo choice, native byte-oriented hash.Hash
+// instead of an in-circuit gnarkhash.FieldHasher. Used by both sign() and
+// computeHash() so the wasm entrypoint always hashes the same way the
+// circuit it's building a witness for does.
+func nativeHasher(algo string) (hash.Hash, error) {
+    switch algo {
+    case "", "mimc":
+        return mimc.NewMiMC(), nil
+    case "poseidon2":
+        return poseidon2.NewPoseidon2(), nil
+    default:
+        return nil, fmt.Errorf("unknown hash algo %q (want \"mimc\" or \"poseidon2\")", algo)
+    }
+}
+
 func sign(this js.Value, args []js.Value) interface{} {
     // args[0] = message hash (decimal string)
+    // args[1] = optional hash algo ("mimc" default, or "poseidon2") - must
+    // match the HashAlgo the circuit.TokenTransferCircuit being proved was
+    // compiled with, or this signature won't verify in-circuit.
     msgStr := args[0].String()
-    
+    algo := ""
+    if len(args) > 1 {
+        algo = args[1].String()
+    }
+
     var msgFr fr.Element
     if _, err := msgFr.SetString(msgStr); err != nil {
         return map[string]interface{}{"error": "Invalid message: " + err.Error()}
     }
     msgBytes := msgFr.Bytes()
-    
+
     // Generate deterministic key pair for benchmark
-    // In a real app, user provides private key. 
+    // In a real app, user provides private key.
     // Here we use a seed based on the message to "simulate" a user signing THIS message,
     // or just a constant seed for simplicity.
     // Let's use constant seed.
-    seed := bytes.NewReader(make([]byte, 32)) 
-    pk, _ := eddsa.GenerateKey(seed) 
-    
-    // Sign
-    // Use MiMC for the signature logic too to match circuit expectation (if circuit uses MiMC for sig verify)
-    // Wait, circuit uses `eddsa.Verify(..., &mimc)`.
-    // So we must pass `mimc.NewMiMC()` here.
-    sigBytes, err := pk.Sign(msgBytes[:], mimc.NewMiMC())
+    seed := bytes.NewReader(make([]byte, 32))
+    pk, _ := eddsa.GenerateKey(seed)
+
+    // Sign with whichever hash the circuit's EdDSA verification (HashAlgo)
+    // expects for its transcript hash.
+    sigHasher, err := nativeHasher(algo)
+    if err != nil {
+        return map[string]interface{}{"error": err.Error()}
+    }
+    sigBytes, err := pk.Sign(msgBytes[:], sigHasher)
     if err != nil {
          return map[string]interface{}{"error": "Sign failed: " + err.Error()}
     }
@@ -97,19 +135,25 @@ func sign(this js.Value, args []js.Value) interface{} {
 
 func computeHash(this js.Value, args []js.Value) interface{} {
 	// args[0] = json string of array of strings/numbers to hash
+	// args[1] = optional hash algo ("mimc" default, or "poseidon2"),
+	// matching circuit.HashAlgo
 	jsonInput := args[0].String()
-	
+	algo := ""
+	if len(args) > 1 {
+		algo = args[1].String()
+	}
+
 	var inputs []string
 	if err := json.Unmarshal([]byte(jsonInput), &inputs); err != nil {
 		return map[string]interface{}{"error": "JSON parse error: " + err.Error()}
 	}
 
-	// Create MiMC hasher
-	// We need correct constants. circuit.go uses standard NewMiMC(api).
-	// Outside circuit (here), we use crypto implementation.
-	// circuit.go does: mimc.NewMiMC(api) which uses BN254 seed "seed" by default.
-	// standard crypto mimc for BN254:
-	h := mimc.NewMiMC() 
+	// We need correct constants - circuit.go's in-circuit hasher
+	// (circuit.newHasher) and this native one must agree bit-for-bit.
+	h, err := nativeHasher(algo)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
 
 	for _, input := range inputs {
 		// Parse input to big.Int or Fr
@@ -248,6 +292,15 @@ func safeProve(jsonInput string) (*ProofResult, error) {
 		OutputAmounts      []string
 		OutputTokenParams  []string
 		OutputOwnerPubKeyX []string
+
+		// UTXO nullifier subsystem (see circuit/tree). MerklePath and
+		// MerklePathIndices come straight out of tree.Tree.Prove.
+		MerkleRoot        string
+		Nullifier         string
+		InputCommitment   string
+		NullifierSecret   string
+		MerklePath        []string
+		MerklePathIndices []string
 	}
 
 	var dto WitnessDTO
@@ -270,7 +323,21 @@ func safeProve(jsonInput string) (*ProofResult, error) {
     // PubKey
     witness.OriginalPubKey.A.X = dto.OriginalPubKey.A.X
     witness.OriginalPubKey.A.Y = dto.OriginalPubKey.A.Y
-    
+
+    // UTXO nullifier subsystem
+    witness.MerkleRoot = dto.MerkleRoot
+    witness.Nullifier = dto.Nullifier
+    witness.InputCommitment = dto.InputCommitment
+    witness.NullifierSecret = dto.NullifierSecret
+
+    if len(dto.MerklePath) != circuit.TreeDepth || len(dto.MerklePathIndices) != circuit.TreeDepth {
+        return nil, fmt.Errorf("Expected %d MerklePath/MerklePathIndices entries, got %d/%d", circuit.TreeDepth, len(dto.MerklePath), len(dto.MerklePathIndices))
+    }
+    for i := 0; i < circuit.TreeDepth; i++ {
+        witness.MerklePath[i] = dto.MerklePath[i]
+        witness.MerklePathIndices[i] = dto.MerklePathIndices[i]
+    }
+
     // Arrays
     if len(dto.OutputAmounts) != 10 {
          return nil, fmt.Errorf("Expected 10 OutputAmounts, got %d", len(dto.OutputAmounts))
@@ -0,0 +1,218 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"syscall/js"
+
+	"gnark-poc/circuit"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// streamingPK is the state of an in-flight chunked proving-key load,
+// started by gnarkInitBegin and finished by gnarkInitCommit. It exists so
+// the whole PK - often hundreds of MB for a non-trivial circuit - never has
+// to live as one contiguous Uint8Array on the JS heap: the page streams it
+// in from fetch's ReadableStream and hands each chunk to gnarkInitChunk as
+// it arrives, instead of the single-shot gnarkInit(pkBytes, vkBytes) above.
+//
+// The load/chunk/commit/cancel mechanics (newStreamingPK, writeChunk,
+// commit, cancel) are plain Go with no js.Value dependency, so
+// streaming_test.go exercises them directly; gnarkInitBegin/Chunk/Commit/
+// CancelInit below are thin js.Value-argument adapters over them.
+type streamingPK struct {
+	pw           *io.PipeWriter
+	hasher       hash.Hash
+	expectedHash []byte // nil if the caller didn't ask for integrity checking
+	received     int
+	total        int
+	progress     func(received, total int) // optional, nil if none was given
+	done         chan error                // result of pk.ReadFrom, delivered by the reader goroutine
+}
+
+var pkStream *streamingPK
+
+// newStreamingPK opens an io.Pipe whose reader is handed to pk.ReadFrom on
+// a background goroutine, and returns the state gnarkInitChunk/Commit/
+// CancelInit (or a test) drive it with. None of the proving key's bytes
+// are read yet - that happens as writeChunk feeds the pipe.
+func newStreamingPK(total int, expectedHash []byte, progress func(received, total int)) *streamingPK {
+	pr, pw := io.Pipe()
+	s := &streamingPK{
+		pw:           pw,
+		hasher:       sha256.New(),
+		expectedHash: expectedHash,
+		total:        total,
+		progress:     progress,
+		done:         make(chan error, 1),
+	}
+
+	go func() {
+		newPk := groth16.NewProvingKey(circuit.InnerCurveID)
+		_, readErr := newPk.ReadFrom(io.TeeReader(pr, s.hasher))
+		if readErr == nil {
+			pk = newPk
+		}
+		s.done <- readErr
+	}()
+
+	return s
+}
+
+// writeChunk feeds one chunk of the proving key into the pipe. offset must
+// equal the number of bytes received so far - chunks must arrive in the
+// same order JS read them off the ReadableStream, offset is checked, not
+// used to seek.
+func (s *streamingPK) writeChunk(offset int, chunk []byte) (received int, err error) {
+	if offset != s.received {
+		return s.received, fmt.Errorf("out-of-order chunk: expected offset %d, got %d", s.received, offset)
+	}
+
+	if _, err := s.pw.Write(chunk); err != nil {
+		return s.received, fmt.Errorf("failed to write chunk (reader may have stopped): %w", err)
+	}
+	s.received += len(chunk)
+
+	if s.progress != nil {
+		s.progress(s.received, s.total)
+	}
+	return s.received, nil
+}
+
+// commit closes the pipe, waits for pk.ReadFrom to finish deserializing
+// everything writeChunk fed it, and verifies the accumulated SHA-256
+// against expectedHash (if any).
+func (s *streamingPK) commit() (sha256Sum []byte, err error) {
+	s.pw.Close()
+	if err := <-s.done; err != nil {
+		return nil, fmt.Errorf("failed to load PK: %w", err)
+	}
+
+	actualHash := s.hasher.Sum(nil)
+	if len(s.expectedHash) > 0 && !bytes.Equal(actualHash, s.expectedHash) {
+		pk = nil
+		return nil, fmt.Errorf("PK hash mismatch: expected %x, got %x", s.expectedHash, actualHash)
+	}
+	return actualHash, nil
+}
+
+// cancel aborts an in-flight chunked load (e.g. the user navigated away
+// mid-fetch): it closes the pipe with an error so the blocked pk.ReadFrom
+// goroutine unwinds instead of leaking, and waits for it to do so. Any
+// writeChunk call after cancel returns ErrClosedPipe immediately rather
+// than blocking, since io.PipeWriter writes never block once the pipe is
+// closed.
+func (s *streamingPK) cancel() {
+	s.pw.CloseWithError(fmt.Errorf("gnarkCancelInit: load cancelled"))
+	<-s.done
+}
+
+// gnarkInitBegin compiles the circuit, loads the (small) verifying key
+// eagerly, and starts a streamingPK for the proving key.
+//
+// args[0] = vk bytes
+// args[1] = total PK size in bytes (informational, for progress reporting)
+// args[2] = expected SHA-256 of the full PK, hex-encoded ("" to skip the check)
+// args[3] = optional progress callback: function(receivedBytes, totalBytes)
+func gnarkInitBegin(this js.Value, args []js.Value) interface{} {
+	var myCircuit circuit.TokenTransferCircuit
+	var err error
+	ccs, err = frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &myCircuit)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+
+	vk = groth16.NewVerifyingKey(circuit.InnerCurveID)
+	if _, err := vk.ReadFrom(bytes.NewReader(getBytes(args[0]))); err != nil {
+		return map[string]interface{}{"error": "Failed to load VK: " + err.Error()}
+	}
+
+	total := args[1].Int()
+
+	var expectedHash []byte
+	if len(args) > 2 && args[2].String() != "" {
+		expectedHash, err = hex.DecodeString(args[2].String())
+		if err != nil {
+			return map[string]interface{}{"error": "Invalid expected hash: " + err.Error()}
+		}
+	}
+
+	var progress func(received, total int)
+	if len(args) > 3 && args[3].Truthy() {
+		cb := args[3]
+		progress = func(received, total int) { cb.Invoke(received, total) }
+	}
+
+	pkStream = newStreamingPK(total, expectedHash, progress)
+
+	return map[string]interface{}{"status": "streaming", "constraints": ccs.GetNbConstraints()}
+}
+
+// gnarkInitChunk feeds one chunk of the proving key into the pipe opened by
+// gnarkInitBegin.
+//
+// args[0] = offset of this chunk within the full PK
+// args[1] = chunk bytes
+func gnarkInitChunk(this js.Value, args []js.Value) (result interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = map[string]interface{}{"error": fmt.Sprintf("Panic in gnarkInitChunk: %v", r)}
+		}
+	}()
+
+	if pkStream == nil {
+		return map[string]interface{}{"error": "gnarkInitChunk called before gnarkInitBegin"}
+	}
+
+	received, err := pkStream.writeChunk(args[0].Int(), getBytes(args[1]))
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+
+	return map[string]interface{}{"received": received, "total": pkStream.total}
+}
+
+// gnarkInitCommit closes the pipe, waits for pk.ReadFrom to finish
+// deserializing everything gnarkInitChunk fed it, and verifies the
+// accumulated SHA-256 against the hash gnarkInitBegin was given (if any).
+func gnarkInitCommit(this js.Value, args []js.Value) interface{} {
+	if pkStream == nil {
+		return map[string]interface{}{"error": "gnarkInitCommit called before gnarkInitBegin"}
+	}
+	s := pkStream
+	pkStream = nil
+
+	actualHash, err := s.commit()
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+
+	return map[string]interface{}{
+		"status":      "ready",
+		"received":    s.received,
+		"sha256":      hex.EncodeToString(actualHash),
+		"constraints": ccs.GetNbConstraints(),
+	}
+}
+
+// gnarkCancelInit aborts an in-flight chunked load (e.g. the user navigated
+// away mid-fetch).
+func gnarkCancelInit(this js.Value, args []js.Value) interface{} {
+	if pkStream == nil {
+		return map[string]interface{}{"status": "nothing to cancel"}
+	}
+	s := pkStream
+	pkStream = nil
+
+	s.cancel()
+	return map[string]interface{}{"status": "cancelled"}
+}
@@ -0,0 +1,172 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"gnark-poc/circuit"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// realPKBytes compiles TokenTransferCircuit, runs a trusted setup, and
+// serializes the resulting proving key - the same shape of bytes a real
+// gnarkInitBegin/Chunk/Commit sequence streams in from fetch.
+func realPKBytes(t *testing.T) []byte {
+	t.Helper()
+
+	var c circuit.TokenTransferCircuit
+	ccs, err := frontend.Compile(circuit.InnerCurveID.ScalarField(), r1cs.NewBuilder, &c)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	realPk, _, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := realPk.WriteTo(&buf); err != nil {
+		t.Fatalf("serialize PK: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestStreamingPartialChunkArrival feeds a real proving key's bytes in
+// several out-of-order-sized chunks (mimicking ReadableStream chunk
+// boundaries that don't line up with anything meaningful) and checks
+// writeChunk accumulates them correctly, the progress callback fires with
+// the running total, and commit produces a pk that matches a direct,
+// unstreamed load byte-for-byte.
+func TestStreamingPartialChunkArrival(t *testing.T) {
+	pkBytes := realPKBytes(t)
+	expectedHash := sha256.Sum256(pkBytes)
+
+	var progressCalls [][2]int
+	s := newStreamingPK(len(pkBytes), expectedHash[:], func(received, total int) {
+		progressCalls = append(progressCalls, [2]int{received, total})
+	})
+
+	chunkSizes := []int{1, 7, len(pkBytes)/3 + 1, 0}
+	offset := 0
+	for _, size := range chunkSizes {
+		if size > len(pkBytes)-offset {
+			size = len(pkBytes) - offset
+		}
+		chunk := pkBytes[offset : offset+size]
+		received, err := s.writeChunk(offset, chunk)
+		if err != nil {
+			t.Fatalf("writeChunk(offset=%d, len=%d): %v", offset, size, err)
+		}
+		offset += size
+		if received != offset {
+			t.Fatalf("received = %d, want %d", received, offset)
+		}
+	}
+	if _, err := s.writeChunk(offset, pkBytes[offset:]); err != nil {
+		t.Fatalf("final writeChunk: %v", err)
+	}
+
+	if len(progressCalls) == 0 {
+		t.Fatal("progress callback was never invoked")
+	}
+	last := progressCalls[len(progressCalls)-1]
+	if last[0] != len(pkBytes) || last[1] != len(pkBytes) {
+		t.Fatalf("last progress call = %v, want [%d %d]", last, len(pkBytes), len(pkBytes))
+	}
+
+	pk = nil
+	actualHash, err := s.commit()
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if !bytes.Equal(actualHash, expectedHash[:]) {
+		t.Fatalf("commit hash = %x, want %x", actualHash, expectedHash)
+	}
+	if pk == nil {
+		t.Fatal("commit did not populate the package-level pk")
+	}
+
+	var streamedBytes bytes.Buffer
+	if _, err := pk.WriteTo(&streamedBytes); err != nil {
+		t.Fatalf("serialize streamed pk: %v", err)
+	}
+	if !bytes.Equal(streamedBytes.Bytes(), pkBytes) {
+		t.Fatal("streamed pk does not round-trip to the same bytes as the direct load")
+	}
+}
+
+// TestStreamingOutOfOrderChunkRejected checks writeChunk rejects a chunk
+// whose offset doesn't match bytes received so far, instead of silently
+// writing it at the wrong position.
+func TestStreamingOutOfOrderChunkRejected(t *testing.T) {
+	s := newStreamingPK(10, nil, nil)
+	defer s.cancel()
+
+	if _, err := s.writeChunk(0, []byte{1, 2, 3}); err != nil {
+		t.Fatalf("first writeChunk: %v", err)
+	}
+	if _, err := s.writeChunk(0, []byte{9}); err == nil {
+		t.Fatal("expected an error writing a chunk at a stale offset")
+	}
+	if _, err := s.writeChunk(100, []byte{9}); err == nil {
+		t.Fatal("expected an error writing a chunk past the received offset")
+	}
+}
+
+// TestStreamingHashMismatchRejected checks commit rejects a load whose
+// accumulated SHA-256 doesn't match the hash gnarkInitBegin was given.
+func TestStreamingHashMismatchRejected(t *testing.T) {
+	pkBytes := realPKBytes(t)
+	wrongHash := sha256.Sum256([]byte("not the proving key"))
+
+	s := newStreamingPK(len(pkBytes), wrongHash[:], nil)
+	if _, err := s.writeChunk(0, pkBytes); err != nil {
+		t.Fatalf("writeChunk: %v", err)
+	}
+	if _, err := s.commit(); err == nil {
+		t.Fatal("expected commit to reject a PK whose hash doesn't match expectedHash")
+	}
+}
+
+// TestStreamingCancelUnblocksReaderAndFailsSubsequentWrites checks cancel
+// unblocks the background pk.ReadFrom goroutine (it's parked waiting for
+// more pipe data at that point, having received nothing) and that a
+// writeChunk call after cancel fails immediately rather than blocking on
+// the now-drained pipe.
+func TestStreamingCancelUnblocksReaderAndFailsSubsequentWrites(t *testing.T) {
+	s := newStreamingPK(10, nil, nil)
+
+	cancelled := make(chan struct{})
+	go func() {
+		s.cancel()
+		close(cancelled)
+	}()
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancel did not unblock the reader goroutine in time")
+	}
+
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := s.writeChunk(0, []byte{1})
+		writeErr <- err
+	}()
+
+	select {
+	case err := <-writeErr:
+		if err == nil {
+			t.Fatal("expected writeChunk after cancel to return an error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("writeChunk after cancel blocked instead of returning an error")
+	}
+}
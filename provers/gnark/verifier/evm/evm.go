@@ -0,0 +1,87 @@
+// Package evm formats a gnark Groth16 BN254 proof and public witness into
+// the exact calldata shape gnark's generated Solidity verifier expects:
+//
+//	function verifyProof(
+//	    uint[2] memory a,
+//	    uint[2][2] memory b,
+//	    uint[2] memory c,
+//	    uint[N] memory input
+//	) public view returns (bool)
+package evm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	groth16bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// Calldata is the Go-side mirror of the Solidity verifier's verifyProof
+// arguments. A/B/C are the proof; Input is the public witness, in the same
+// order the circuit declared its public variables.
+type Calldata struct {
+	A     [2]*big.Int
+	B     [2][2]*big.Int
+	C     [2]*big.Int
+	Input []*big.Int
+}
+
+// FormatProof converts a BN254 Groth16 proof into Calldata's a/b/c fields.
+// b's coordinates are swapped (A1 before A0) relative to gnark-crypto's
+// native G2 encoding: the EVM's alt_bn128 pairing precompile expects each
+// Fp2 element as [imaginary, real], the opposite of gnark-crypto's (A0,
+// A1) = (real, imaginary) ordering.
+func FormatProof(proof groth16.Proof) (a [2]*big.Int, b [2][2]*big.Int, c [2]*big.Int, err error) {
+	p, ok := proof.(*groth16bn254.Proof)
+	if !ok {
+		return a, b, c, fmt.Errorf("evm: expected a BN254 groth16 proof, got %T", proof)
+	}
+
+	a = [2]*big.Int{p.Ar.X.BigInt(new(big.Int)), p.Ar.Y.BigInt(new(big.Int))}
+	c = [2]*big.Int{p.Krs.X.BigInt(new(big.Int)), p.Krs.Y.BigInt(new(big.Int))}
+	b = [2][2]*big.Int{
+		{p.Bs.X.A1.BigInt(new(big.Int)), p.Bs.X.A0.BigInt(new(big.Int))},
+		{p.Bs.Y.A1.BigInt(new(big.Int)), p.Bs.Y.A0.BigInt(new(big.Int))},
+	}
+	return a, b, c, nil
+}
+
+// FormatPublicWitness flattens a public witness into the decimal big.Int
+// vector the Solidity verifier's `input` argument expects, in declaration
+// order.
+func FormatPublicWitness(w witness.Witness) ([]*big.Int, error) {
+	pub, err := w.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	vec := pub.Vector()
+	values, ok := vec.(fr.Vector)
+	if !ok {
+		return nil, fmt.Errorf("evm: unsupported public witness vector type %T", vec)
+	}
+
+	out := make([]*big.Int, len(values))
+	for i := range values {
+		out[i] = values[i].BigInt(new(big.Int))
+	}
+	return out, nil
+}
+
+// NewCalldata builds the full Calldata for a proof + public witness pair,
+// ready to pass to a deployed Verifier.sol's verifyProof.
+func NewCalldata(proof groth16.Proof, publicWitness witness.Witness) (*Calldata, error) {
+	a, b, c, err := FormatProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	input, err := FormatPublicWitness(publicWitness)
+	if err != nil {
+		return nil, err
+	}
+	return &Calldata{A: a, B: b, C: c, Input: input}, nil
+}
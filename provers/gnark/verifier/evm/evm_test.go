@@ -0,0 +1,238 @@
+package evm_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os/exec"
+	"testing"
+
+	"gnark-poc/circuit"
+	"gnark-poc/verifier/evm"
+	"gnark-poc/verifier/solidity"
+
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	bn254eddsa "github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// TestVerifierSolDeploysAndVerifies exercises the full off-chain-prover to
+// on-chain-verifier path this package exists for: compile the circuit,
+// prove a real (all-zero UTXO/amount) transfer signed with a genuine
+// EdDSA key, export the verifying key as Verifier.sol (verifier/solidity),
+// deploy it on an in-process EVM, and check the deployed contract's
+// verifyProof agrees with gnark's own Verify for the calldata
+// evm.NewCalldata produced.
+//
+// Requires solc on PATH to compile the generated contract; skipped
+// otherwise, since this repo has no build toolchain pinned for it.
+func TestVerifierSolDeploysAndVerifies(t *testing.T) {
+	if _, err := exec.LookPath("solc"); err != nil {
+		t.Skip("solc not found on PATH, skipping Solidity deploy test")
+	}
+
+	var c circuit.TokenTransferCircuit
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &c)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	w, err := zeroTransferWitness()
+	if err != nil {
+		t.Fatalf("build witness: %v", err)
+	}
+	fullWitness, err := frontend.NewWitness(w, ecc.BN254.ScalarField())
+	if err != nil {
+		t.Fatalf("witness: %v", err)
+	}
+	publicWitness, err := fullWitness.Public()
+	if err != nil {
+		t.Fatalf("public witness: %v", err)
+	}
+
+	proof, err := groth16.Prove(ccs, pk, fullWitness)
+	if err != nil {
+		t.Fatalf("prove: %v", err)
+	}
+	if err := groth16.Verify(proof, vk, publicWitness); err != nil {
+		t.Fatalf("native verify failed, witness is not actually valid: %v", err)
+	}
+
+	var solBuf bytes.Buffer
+	if err := solidity.Export(vk, &solBuf); err != nil {
+		t.Fatalf("export solidity: %v", err)
+	}
+
+	abiJSON, bytecode := compileSolidity(t, solBuf.String())
+	parsedABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		t.Fatalf("parse abi: %v", err)
+	}
+
+	calldata, err := evm.NewCalldata(proof, publicWitness)
+	if err != nil {
+		t.Fatalf("calldata: %v", err)
+	}
+
+	key, err := crypto.HexToECDSA("d1f6bb4e866699901e7607ec123ac6a7c33b6b28f7bd337c2726c044e4d7441b")
+	if err != nil {
+		t.Fatalf("test key: %v", err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		t.Fatalf("transactor: %v", err)
+	}
+
+	sim := simulated.NewBackend(types.GenesisAlloc{
+		auth.From: {Balance: new(big.Int).Lsh(big.NewInt(1), 64)},
+	})
+	defer sim.Close()
+	client := sim.Client()
+
+	address, tx, contract, err := bind.DeployContract(auth, parsedABI, bytecode, client)
+	if err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+	sim.Commit()
+	if _, err := bind.WaitDeployed(context.Background(), client, tx); err != nil {
+		t.Fatalf("wait deployed: %v", err)
+	}
+	if address == (common.Address{}) {
+		t.Fatal("deployed contract has zero address")
+	}
+
+	var out bool
+	if err := contract.Call(&bind.CallOpts{}, &[]interface{}{&out}, "verifyProof", calldata.A, calldata.B, calldata.C, calldata.Input); err != nil {
+		t.Fatalf("verifyProof call: %v", err)
+	}
+	if !out {
+		t.Fatal("on-chain verifyProof returned false for a proof gnark's own Verify accepted")
+	}
+}
+
+// compileSolidity shells out to solc to turn source into ABI JSON + runtime
+// bytecode, the two things bind.DeployContract needs.
+func compileSolidity(t *testing.T, source string) (abiJSON []byte, bytecode []byte) {
+	t.Helper()
+
+	cmd := exec.Command("solc", "--combined-json", "abi,bin", "--optimize", "-")
+	cmd.Stdin = bytes.NewReader([]byte(source))
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("solc: %v", err)
+	}
+
+	var parsed struct {
+		Contracts map[string]struct {
+			ABI json.RawMessage `json:"abi"`
+			Bin string          `json:"bin"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(out, &parsed); err != nil {
+		t.Fatalf("parse solc output: %v", err)
+	}
+
+	for _, entry := range parsed.Contracts {
+		return entry.ABI, common.FromHex(entry.Bin)
+	}
+	t.Fatal("solc produced no contracts")
+	return nil, nil
+}
+
+// zeroTransferWitness builds a real, satisfiable TokenTransferCircuit
+// witness: every amount and UTXO field is zero, MerkleRoot/Nullifier are
+// the native MiMC results Define's constraints 5/6 actually require for an
+// all-zero leaf (not the literal zero value), and the signature is a
+// genuine EdDSA signature over the circuit's message, built the same way
+// cmd/wasm/main.go's sign() builds one for the HashMiMC default.
+func zeroTransferWitness() (*circuit.TokenTransferCircuit, error) {
+	var w circuit.TokenTransferCircuit
+
+	w.InputAmount = 0
+	w.TokenParams = 0
+	w.InputCommitment = 0
+	w.NullifierSecret = 0
+	for i := 0; i < circuit.MaxOutputs; i++ {
+		w.OutputAmounts[i] = 0
+		w.OutputTokenParams[i] = 0
+		w.OutputOwnerPubKeyX[i] = 0
+	}
+	for i := 0; i < circuit.TreeDepth; i++ {
+		w.MerklePath[i] = 0
+		w.MerklePathIndices[i] = 0
+	}
+
+	root := big.NewInt(0)
+	for i := 0; i < circuit.TreeDepth; i++ {
+		root = mimcHash(root, big.NewInt(0))
+	}
+	w.MerkleRoot = root
+	w.Nullifier = mimcHash(big.NewInt(0), big.NewInt(0))
+
+	// outputData0 == outputData1 == hash(0,0,0) since every output field is
+	// zero; outputCommitment folds those two together.
+	outputData := mimcHash(big.NewInt(0), big.NewInt(0), big.NewInt(0))
+	outputCommitment := mimcHash(outputData, outputData)
+	message := mimcHash(big.NewInt(0), big.NewInt(0), outputCommitment)
+
+	seed := bytes.NewReader(make([]byte, 32))
+	signerKey, err := bn254eddsa.GenerateKey(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgFr bn254fr.Element
+	msgFr.SetBigInt(message)
+	msgBytes := msgFr.Bytes()
+
+	sigBytes, err := signerKey.Sign(msgBytes[:], mimc.NewMiMC())
+	if err != nil {
+		return nil, err
+	}
+	var sig bn254eddsa.Signature
+	if _, err := sig.SetBytes(sigBytes); err != nil {
+		return nil, err
+	}
+
+	w.Signature.R.X = sig.R.X.String()
+	w.Signature.R.Y = sig.R.Y.String()
+	w.Signature.S = new(bn254fr.Element).SetBytes(sig.S[:]).String()
+	w.OriginalPubKey.A.X = signerKey.PublicKey.A.X.String()
+	w.OriginalPubKey.A.Y = signerKey.PublicKey.A.Y.String()
+
+	return &w, nil
+}
+
+// mimcHash mirrors the in-circuit
+// mimc.NewMiMC(api).Write(vals...).Sum() sequence natively, over
+// gnark-crypto's bn254 MiMC, for the test's native witness construction.
+func mimcHash(vals ...*big.Int) *big.Int {
+	h := mimc.NewMiMC()
+	for _, v := range vals {
+		var e bn254fr.Element
+		e.SetBigInt(v)
+		b := e.Bytes()
+		h.Write(b[:])
+	}
+	var out bn254fr.Element
+	out.SetBytes(h.Sum(nil))
+	return out.BigInt(new(big.Int))
+}
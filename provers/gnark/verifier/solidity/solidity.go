@@ -0,0 +1,28 @@
+// Package solidity exports a Groth16 verifying key as a standalone Solidity
+// verifier contract, so TokenTransferCircuit proofs produced off-chain (CLI
+// or WASM) can be checked on-chain without trusting whoever relays them.
+package solidity
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark/backend/groth16"
+)
+
+// exporter is the subset of gnark's curve-specific verifying key types
+// (e.g. *groth16_bn254.VerifyingKey) that know how to render themselves as
+// a Solidity contract. groth16.VerifyingKey itself is just a marker
+// interface, so this needs a type assertion to get at it.
+type exporter interface {
+	ExportSolidity(w io.Writer) error
+}
+
+// Export writes a Verifier.sol contract for vk to w.
+func Export(vk groth16.VerifyingKey, w io.Writer) error {
+	e, ok := vk.(exporter)
+	if !ok {
+		return fmt.Errorf("solidity: verifying key of type %T does not support Solidity export", vk)
+	}
+	return e.ExportSolidity(w)
+}